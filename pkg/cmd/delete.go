@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+)
+
+// dryRunClient and dryRunServer mirror kubectl's --dry-run values:
+// dryRunClient never talks to the API server at all, while dryRunServer
+// issues the real request with DryRun: [All] so admission webhooks (which a
+// client-side dry-run can't exercise) still run.
+const (
+	dryRunClient = "client"
+	dryRunServer = "server"
+)
+
+// deleteCandidate deletes the resource info describes, honoring --dry-run:
+// dryRunClient skips the API call entirely, dryRunServer sends a
+// DryRun=[All] DELETE, and any other value (including "") performs a real
+// delete.
+func deleteCandidate(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, info *resource.Info, dryRun string) error {
+	if dryRun == dryRunClient {
+		return nil
+	}
+
+	gvk := info.Object.GetObjectKind().GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("failed to map %s for deletion: %w", gvk, err)
+	}
+
+	resourceClient := dynamicClient.Resource(mapping.Resource)
+
+	opts := metav1.DeleteOptions{}
+	if dryRun == dryRunServer {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		err = resourceClient.Namespace(info.Namespace).Delete(ctx, info.Name, opts)
+	} else {
+		err = resourceClient.Delete(ctx, info.Name, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s %s: %w", gvk.Kind, info.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestDeleteCandidateDryRunClientSkipsAPICall(t *testing.T) {
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-old", Namespace: "default"}}
+	rs.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(kindReplicaSet))
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, rs)
+	mapper := testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)
+
+	info := &resource.Info{Object: rs, Name: "web-old", Namespace: "default"}
+
+	if err := deleteCandidate(context.Background(), dynamicClient, mapper, info, dryRunClient); err != nil {
+		t.Fatalf("deleteCandidate() error = %v", err)
+	}
+
+	gvr := appsv1.SchemeGroupVersion.WithResource("replicasets")
+	if _, err := dynamicClient.Resource(gvr).Namespace("default").Get(context.Background(), "web-old", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected web-old to still exist after a client-side dry run, Get() error = %v", err)
+	}
+}
+
+func TestDeleteCandidateRealDeleteRemovesResource(t *testing.T) {
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-old", Namespace: "default"}}
+	rs.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(kindReplicaSet))
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, rs)
+	mapper := testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)
+
+	info := &resource.Info{Object: rs, Name: "web-old", Namespace: "default"}
+
+	if err := deleteCandidate(context.Background(), dynamicClient, mapper, info, ""); err != nil {
+		t.Fatalf("deleteCandidate() error = %v", err)
+	}
+
+	gvr := appsv1.SchemeGroupVersion.WithResource("replicasets")
+	if _, err := dynamicClient.Resource(gvr).Namespace("default").Get(context.Background(), "web-old", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected web-old to be deleted")
+	}
+}
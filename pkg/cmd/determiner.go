@@ -3,38 +3,129 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"golang.org/x/sync/errgroup"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	apiregistrationclientset "k8s.io/kube-aggregator/pkg/client/clientset/versioned"
 )
 
+// defaultMaxConcurrency bounds how many of the independent API calls
+// newDeterminer issues to populate its usage caches run at once, so
+// kubectl-reap doesn't hammer the API server on a namespace that has many
+// resource kinds to cross-reference. Overridable via --max-concurrency.
+const defaultMaxConcurrency = 8
+
 const (
 	kindConfigMap             = "ConfigMap"
 	kindSecret                = "Secret"
 	kindPod                   = "Pod"
+	kindPersistentVolume      = "PersistentVolume"
 	kindPersistentVolumeClaim = "PersistentVolumeClaim"
 	kindPodDisruptionBudget   = "PodDisruptionBudget"
+	kindJob                   = "Job"
+	kindReplicaSet            = "ReplicaSet"
 )
 
+// jobCompletionTTL is how long a finished Job is kept around before it
+// becomes a prune candidate, mirroring the grace period the built-in
+// TTL-after-finished controller gives jobs that don't set their own
+// spec.ttlSecondsAfterFinished.
+const jobCompletionTTL = 24 * time.Hour
+
+// deploymentRevisionAnnotation records, on both a Deployment and the
+// ReplicaSets it owns, which rollout revision a ReplicaSet belongs to.
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
 // determiner determines whether a resource should be pruned.
 type determiner struct {
-	usedConfigMaps             map[string]struct{} // key=ConfigMap.Name
-	usedSecrets                map[string]struct{} // key=Secret.Name
-	usedPersistentVolumeClaims map[string]struct{} // key=PersistentVolumeClaim.Name
+	usedConfigMaps             map[string]struct{} // key=namespace/name
+	usedSecrets                map[string]struct{} // key=namespace/name
+	usedPersistentVolumeClaims map[string]struct{} // key=namespace/name
+
+	// existingPersistentVolumeClaims is the set of PersistentVolumeClaims
+	// that still exist anywhere in the cluster, keyed by "namespace/name".
+	// It is used to tell whether a PersistentVolume's claimRef is orphaned,
+	// which is a cluster-scoped question regardless of --all-namespaces.
+	existingPersistentVolumeClaims map[string]struct{} // key=namespace/name
+
+	// currentReplicaSets holds the names of ReplicaSets that represent the
+	// current revision of the Deployment that owns them, even if they have
+	// been scaled to zero replicas, so they aren't mistaken for leftovers
+	// from a previous rollout.
+	currentReplicaSets map[string]struct{} // key=ReplicaSet.Name
+
+	// respectOwnerRefs and owners implement --respect-owner-refs: a
+	// candidate owned by a controller that's still alive is skipped rather
+	// than pruned, to avoid deleting objects Helm/an Operator/a StatefulSet
+	// would just recreate.
+	respectOwnerRefs bool
+	owners           *ownerResolver
 
 	pods []*corev1.Pod
+
+	// pdbGroupVersion is the PodDisruptionBudget API version preferred by the
+	// connected API server, as reported by discovery. It is "policy/v1" on
+	// Kubernetes 1.21+ and falls back to "policy/v1beta1" on older clusters
+	// where the GA version isn't served yet (the beta version was removed
+	// entirely in Kubernetes 1.25).
+	pdbGroupVersion string
+}
+
+// determinerConfig bundles the clients and flags newDeterminer needs. It
+// replaces what used to be a plain positional parameter list, which stopped
+// being readable once kubectl-reap grew enough resource kinds and usage
+// sources to need more than one client.
+type determinerConfig struct {
+	clientset             kubernetes.Interface
+	dynamicClient         dynamic.Interface
+	apiregistrationClient apiregistrationclientset.Interface
+	mapper                meta.RESTMapper
+
+	resources *resource.Result
+	namespace string
+
+	// allNamespaces mirrors --all-namespaces: when true, newDeterminer's
+	// informerCache covers every namespace instead of just namespace. The
+	// PersistentVolumeClaim list used for PersistentVolume candidates is
+	// cluster-wide either way.
+	allNamespaces bool
+
+	respectOwnerRefs bool
+	ignoreOwners     []string
+
+	// maxConcurrency bounds how many of newDeterminer's independent List
+	// calls run at once; zero means defaultMaxConcurrency. Candidate
+	// filtering by --selector/--field-selector happens earlier, on the
+	// resource.Builder that produces resources, so it isn't repeated here.
+	maxConcurrency int
 }
 
-func newDeterminer(clientset *kubernetes.Clientset, r *resource.Result, namespace string) (*determiner, error) {
+func newDeterminer(cfg determinerConfig) (*determiner, error) {
+	clientset := cfg.clientset
+	r := cfg.resources
+	namespace := cfg.namespace
+
 	var (
 		pruneConfigMaps             bool
 		pruneSecrets                bool
+		prunePersistentVolumes      bool
 		prunePersistentVolumeClaims bool
 		prunePodDisruptionBudgets   bool
+		pruneReplicaSets            bool
 	)
 
 	if err := r.Visit(func(info *resource.Info, err error) error {
@@ -43,121 +134,337 @@ func newDeterminer(clientset *kubernetes.Clientset, r *resource.Result, namespac
 			pruneConfigMaps = true
 		case kindSecret:
 			pruneSecrets = true
+		case kindPersistentVolume:
+			prunePersistentVolumes = true
 		case kindPersistentVolumeClaim:
 			prunePersistentVolumeClaims = true
 		case kindPodDisruptionBudget:
 			prunePodDisruptionBudgets = true
+		case kindReplicaSet:
+			pruneReplicaSets = true
 		}
 		return nil
 	}); err != nil {
 		return nil, err
 	}
 
-	d := &determiner{}
+	d := &determiner{
+		respectOwnerRefs: cfg.respectOwnerRefs,
+		owners:           newOwnerResolver(cfg.dynamicClient, cfg.mapper, cfg.ignoreOwners),
+	}
 
-	ctx := context.Background()
+	g, ctx := errgroup.WithContext(context.Background())
+	maxConcurrency := cfg.maxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+	g.SetLimit(maxConcurrency)
+
+	// cacheNamespace is "" under --all-namespaces, so the informer cache
+	// built below (and every one-shot List call alongside it) covers the
+	// whole cluster instead of just namespace.
+	cacheNamespace := namespace
+	if cfg.allNamespaces {
+		cacheNamespace = ""
+	}
 
-	if pruneConfigMaps || pruneSecrets || prunePersistentVolumeClaims || prunePodDisruptionBudgets {
+	// The informer cache backs every kind it covers (Pods, ServiceAccounts,
+	// Ingresses, Deployments, StatefulSets, DaemonSets, ReplicaSets;
+	// PersistentVolumeClaim usage is derived from the cached Pods): a single List+Watch
+	// handshake per kind instead of the one-off List call newDeterminer used
+	// to issue.
+	var cache *informerCache
+	if pruneConfigMaps || pruneSecrets || prunePersistentVolumeClaims || prunePodDisruptionBudgets || pruneReplicaSets {
 		var err error
-		d.pods, err = listPods(ctx, clientset, namespace)
+		cache, err = newInformerCache(ctx, clientset, cacheNamespace)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to build informer cache: %w", err)
 		}
 	}
 
+	// The remaining dependencies don't have an informer above (cluster-scoped
+	// oddities a short-lived CLI run doesn't benefit from watching), so they
+	// are still fetched with one-shot, independent List calls, run
+	// concurrently and bounded by maxConcurrency.
+	var (
+		controllerPodSpecs  []namespacedPodSpec
+		caBundleAnnotations []map[string]string
+		certificates        []*unstructured.Unstructured
+		existingPVCs        []*corev1.PersistentVolumeClaim
+	)
+
+	if pruneConfigMaps || pruneSecrets {
+		g.Go(func() error {
+			var err error
+			controllerPodSpecs, err = listControllerPodSpecs(ctx, clientset, cache, cacheNamespace)
+			return err
+		})
+
+		g.Go(func() error {
+			var err error
+			caBundleAnnotations, err = listWebhookAndAPIServiceAnnotations(ctx, clientset, cfg.apiregistrationClient)
+			return err
+		})
+	}
+
+	if pruneSecrets {
+		g.Go(func() error {
+			var err error
+			certificates, err = listCertManagerCertificates(ctx, cfg.dynamicClient, cacheNamespace)
+			return err
+		})
+	}
+
+	if prunePersistentVolumes {
+		g.Go(func() error {
+			var err error
+			existingPVCs, err = listAllPersistentVolumeClaims(ctx, clientset)
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var pods []*corev1.Pod
+	if cache != nil {
+		var err error
+		pods, err = cache.pods.List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods from informer cache: %w", err)
+		}
+	}
+	d.pods = pods
+
+	// Controller Pod templates count as "using" a ConfigMap/Secret even when
+	// the controller is scaled to zero and has no live Pods to scan.
+	allPodSpecs := append(podSpecsOf(pods), controllerPodSpecs...)
+
 	if pruneConfigMaps {
-		d.usedConfigMaps = detectUsedConfigMaps(d.pods)
+		d.usedConfigMaps = unionSets(
+			detectUsedConfigMaps(allPodSpecs),
+			detectCABundleConfigMaps(caBundleAnnotations),
+		)
 	}
 
 	if pruneSecrets {
-		sas, err := listServiceAccounts(ctx, clientset, namespace)
+		sas, err := cache.serviceAccounts.List(labels.Everything())
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to list serviceaccounts from informer cache: %w", err)
+		}
+		ingresses, err := cache.ingresses.List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ingresses from informer cache: %w", err)
 		}
-		d.usedSecrets = detectUsedSecrets(d.pods, sas)
+
+		d.usedSecrets = unionSets(
+			detectUsedSecrets(allPodSpecs, sas),
+			detectIngressSecrets(ingresses),
+			detectCABundleSecrets(caBundleAnnotations),
+			detectCertManagerSecrets(certificates),
+		)
 	}
 
 	if prunePersistentVolumeClaims {
-		d.usedPersistentVolumeClaims = detectUsedPersistentVolumeClaims(d.pods)
+		d.usedPersistentVolumeClaims = detectUsedPersistentVolumeClaims(pods)
+	}
+
+	if prunePodDisruptionBudgets {
+		d.pdbGroupVersion = preferredPodDisruptionBudgetVersion(clientset)
+	}
+
+	if prunePersistentVolumes {
+		d.existingPersistentVolumeClaims = detectExistingPersistentVolumeClaims(existingPVCs)
+	}
+
+	if pruneReplicaSets {
+		deployments, err := cache.deployments.List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments from informer cache: %w", err)
+		}
+		replicaSets, err := cache.replicaSets.List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list replicasets from informer cache: %w", err)
+		}
+
+		d.currentReplicaSets = detectCurrentReplicaSets(deployments, replicaSets)
 	}
 
 	return d, nil
 }
 
+// preferredPodDisruptionBudgetVersion discovers which PodDisruptionBudget API
+// version the connected API server serves, preferring the GA policy/v1 over
+// the deprecated policy/v1beta1 (removed in Kubernetes 1.25+).
+func preferredPodDisruptionBudgetVersion(clientset kubernetes.Interface) string {
+	if _, err := clientset.Discovery().ServerResourcesForGroupVersion(policyv1.SchemeGroupVersion.String()); err == nil {
+		return policyv1.SchemeGroupVersion.String()
+	}
+	return policyv1beta1.SchemeGroupVersion.String()
+}
+
+// pruneDecision is the outcome of evaluating a single candidate resource: not
+// just whether to prune it, but a short human-readable reason. This backs
+// both plain pruning and the --output=json|yaml prune plan, which reports
+// Reason alongside each candidate.
+type pruneDecision struct {
+	Prune  bool
+	Reason string
+}
+
 // determinePrune determines whether a resource should be pruned.
-func (d *determiner) determinePrune(info *resource.Info) (bool, error) {
+func (d *determiner) determinePrune(info *resource.Info) (pruneDecision, error) {
 	switch kind := info.Object.GetObjectKind().GroupVersionKind().Kind; kind {
 	case kindConfigMap:
-		if _, ok := d.usedConfigMaps[info.Name]; !ok {
-			return true, nil
+		if _, ok := d.usedConfigMaps[info.Namespace+"/"+info.Name]; !ok {
+			return d.checkOwnerRefs(info, "ConfigMap not referenced by any Pod, controller, or webhook/APIService caBundle")
 		}
 
 	case kindSecret:
-		if _, ok := d.usedSecrets[info.Name]; !ok {
-			return true, nil
+		if _, ok := d.usedSecrets[info.Namespace+"/"+info.Name]; !ok {
+			return d.checkOwnerRefs(info, "Secret not referenced by any Pod, ServiceAccount, Ingress, webhook, APIService, Certificate, or controller")
 		}
 
+	case kindPersistentVolume:
+		pv, err := infoToPersistentVolume(info)
+		if err != nil {
+			return pruneDecision{}, err
+		}
+
+		if !detectOrphanedPersistentVolume(pv, d.existingPersistentVolumeClaims) {
+			return pruneDecision{}, nil
+		}
+		return pruneDecision{Prune: true, Reason: persistentVolumePruneReason(pv)}, nil
+
 	case kindPersistentVolumeClaim:
-		if _, ok := d.usedPersistentVolumeClaims[info.Name]; !ok {
-			return true, nil
+		if _, ok := d.usedPersistentVolumeClaims[info.Namespace+"/"+info.Name]; !ok {
+			return d.checkOwnerRefs(info, "PersistentVolumeClaim not mounted by any Pod")
 		}
 
 	case kindPod:
 		pod, err := infoToPod(info)
 		if err != nil {
-			return false, err
+			return pruneDecision{}, err
 		}
 
-		if pod.Status.Phase != corev1.PodRunning {
-			return true, nil
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			return pruneDecision{Prune: true, Reason: "Pod has completed (phase Succeeded)"}, nil
+		case corev1.PodFailed:
+			return pruneDecision{Prune: true, Reason: "Pod has failed (phase Failed)"}, nil
+		}
+
+	case kindJob:
+		job, err := infoToJob(info)
+		if err != nil {
+			return pruneDecision{}, err
+		}
+
+		if !detectPrunableJob(job) {
+			return pruneDecision{}, nil
 		}
+		return pruneDecision{Prune: true, Reason: fmt.Sprintf("Job finished more than %s ago", jobCompletionTTL)}, nil
+
+	case kindReplicaSet:
+		rs, err := infoToReplicaSet(info)
+		if err != nil {
+			return pruneDecision{}, err
+		}
+
+		if !detectPrunableReplicaSet(rs, d.currentReplicaSets) {
+			return pruneDecision{}, nil
+		}
+		return pruneDecision{Prune: true, Reason: "ReplicaSet is scaled to zero and isn't the current revision of its Deployment"}, nil
 
 	case kindPodDisruptionBudget:
-		pdb, err := infoToPodDisruptionBudget(info)
+		selector, err := infoToPodDisruptionBudgetSelector(info)
 		if err != nil {
-			return false, err
+			return pruneDecision{}, err
 		}
 
-		used, err := d.determineUsedPodDisruptionBudget(pdb)
+		used, err := d.determineUsedPodDisruptionBudget(info.Name, selector)
 		if err != nil {
-			return false, err
+			return pruneDecision{}, err
+		}
+		if used {
+			return pruneDecision{}, nil
 		}
-		return !used, nil
+		return pruneDecision{Prune: true, Reason: "PodDisruptionBudget selector matches no Pod"}, nil
 
 	default:
-		return false, fmt.Errorf("unsupported kind: %s/%s", kind, info.Name)
+		return pruneDecision{}, fmt.Errorf("unsupported kind: %s/%s", kind, info.Name)
 	}
 
-	return false, nil
+	return pruneDecision{}, nil
 }
 
-func detectUsedConfigMaps(pods []*corev1.Pod) map[string]struct{} {
-	usedConfigMaps := make(map[string]struct{})
+// persistentVolumePruneReason explains why detectOrphanedPersistentVolume
+// judged a PersistentVolume to be orphaned.
+func persistentVolumePruneReason(pv *corev1.PersistentVolume) string {
+	if pv.Status.Phase == corev1.VolumeReleased {
+		return "PersistentVolume has been Released by its claim"
+	}
+	return "PersistentVolume's claimRef no longer exists"
+}
 
+// namespacedPodSpec pairs a PodSpec with the namespace it was read from, so
+// detectUsedConfigMaps/detectUsedSecrets can key their usage sets by
+// "namespace/name" rather than bare name - necessary now that
+// --all-namespaces can populate them from more than one namespace at once.
+type namespacedPodSpec struct {
+	namespace string
+	spec      corev1.PodSpec
+}
+
+// podSpecsOf extracts each Pod's spec, so Pod-derived and controller
+// Pod-template-derived specs can be scanned by the same code.
+func podSpecsOf(pods []*corev1.Pod) []namespacedPodSpec {
+	specs := make([]namespacedPodSpec, 0, len(pods))
 	for _, pod := range pods {
-		for _, container := range pod.Spec.Containers {
+		specs = append(specs, namespacedPodSpec{namespace: pod.Namespace, spec: pod.Spec})
+	}
+	return specs
+}
+
+// unionSets merges any number of string sets into one.
+func unionSets(sets ...map[string]struct{}) map[string]struct{} {
+	union := make(map[string]struct{})
+	for _, set := range sets {
+		for key := range set {
+			union[key] = struct{}{}
+		}
+	}
+	return union
+}
+
+func detectUsedConfigMaps(podSpecs []namespacedPodSpec) map[string]struct{} {
+	usedConfigMaps := make(map[string]struct{})
+
+	for _, ps := range podSpecs {
+		for _, container := range ps.spec.Containers {
 			for _, envFrom := range container.EnvFrom {
 				if envFrom.ConfigMapRef != nil {
-					usedConfigMaps[envFrom.ConfigMapRef.Name] = struct{}{}
+					usedConfigMaps[ps.namespace+"/"+envFrom.ConfigMapRef.Name] = struct{}{}
 				}
 			}
 
 			for _, env := range container.Env {
 				if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
-					usedConfigMaps[env.ValueFrom.ConfigMapKeyRef.Name] = struct{}{}
+					usedConfigMaps[ps.namespace+"/"+env.ValueFrom.ConfigMapKeyRef.Name] = struct{}{}
 				}
 			}
 		}
 
-		for _, volume := range pod.Spec.Volumes {
+		for _, volume := range ps.spec.Volumes {
 			if volume.ConfigMap != nil {
-				usedConfigMaps[volume.ConfigMap.Name] = struct{}{}
+				usedConfigMaps[ps.namespace+"/"+volume.ConfigMap.Name] = struct{}{}
 			}
 
 			if volume.Projected != nil {
 				for _, source := range volume.Projected.Sources {
 					if source.ConfigMap != nil {
-						usedConfigMaps[source.ConfigMap.Name] = struct{}{}
+						usedConfigMaps[ps.namespace+"/"+source.ConfigMap.Name] = struct{}{}
 					}
 				}
 			}
@@ -167,44 +474,48 @@ func detectUsedConfigMaps(pods []*corev1.Pod) map[string]struct{} {
 	return usedConfigMaps
 }
 
-func detectUsedSecrets(pods []*corev1.Pod, sas []*corev1.ServiceAccount) map[string]struct{} {
+func detectUsedSecrets(podSpecs []namespacedPodSpec, sas []*corev1.ServiceAccount) map[string]struct{} {
 	usedSecrets := make(map[string]struct{})
 
-	// Add Secrets used in Pods
-	for _, pod := range pods {
-		for _, container := range pod.Spec.Containers {
+	// Add Secrets used in Pods (and controller Pod templates)
+	for _, ps := range podSpecs {
+		for _, container := range ps.spec.Containers {
 			for _, envFrom := range container.EnvFrom {
 				if envFrom.SecretRef != nil {
-					usedSecrets[envFrom.SecretRef.Name] = struct{}{}
+					usedSecrets[ps.namespace+"/"+envFrom.SecretRef.Name] = struct{}{}
 				}
 			}
 
 			for _, env := range container.Env {
 				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
-					usedSecrets[env.ValueFrom.SecretKeyRef.Name] = struct{}{}
+					usedSecrets[ps.namespace+"/"+env.ValueFrom.SecretKeyRef.Name] = struct{}{}
 				}
 			}
 		}
 
-		for _, volume := range pod.Spec.Volumes {
+		for _, volume := range ps.spec.Volumes {
 			if volume.Secret != nil {
-				usedSecrets[volume.Secret.SecretName] = struct{}{}
+				usedSecrets[ps.namespace+"/"+volume.Secret.SecretName] = struct{}{}
 			}
 
 			if volume.Projected != nil {
 				for _, source := range volume.Projected.Sources {
 					if source.Secret != nil {
-						usedSecrets[source.Secret.Name] = struct{}{}
+						usedSecrets[ps.namespace+"/"+source.Secret.Name] = struct{}{}
 					}
 				}
 			}
 		}
+
+		for _, ref := range ps.spec.ImagePullSecrets {
+			usedSecrets[ps.namespace+"/"+ref.Name] = struct{}{}
+		}
 	}
 
 	// Add Secrets used in ServiceAccounts
 	for _, sa := range sas {
 		for _, secret := range sa.Secrets {
-			usedSecrets[secret.Name] = struct{}{}
+			usedSecrets[sa.Namespace+"/"+secret.Name] = struct{}{}
 		}
 	}
 
@@ -219,17 +530,57 @@ func detectUsedPersistentVolumeClaims(pods []*corev1.Pod) map[string]struct{} {
 			if volume.PersistentVolumeClaim == nil {
 				continue
 			}
-			usedPersistentVolumeClaims[volume.PersistentVolumeClaim.ClaimName] = struct{}{}
+			usedPersistentVolumeClaims[pod.Namespace+"/"+volume.PersistentVolumeClaim.ClaimName] = struct{}{}
 		}
 	}
 
 	return usedPersistentVolumeClaims
 }
 
-func (d *determiner) determineUsedPodDisruptionBudget(pdb *policyv1beta1.PodDisruptionBudget) (bool, error) {
-	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+// infoToTyped converts info.Object into out, which must be a pointer to a
+// typed API struct (e.g. *policyv1.PodDisruptionBudget). resource.Builder's
+// Unstructured() mode always decodes candidates as *unstructured.Unstructured,
+// so this goes through runtime.DefaultUnstructuredConverter rather than a
+// type assertion against the typed struct, which would never match.
+func infoToTyped(info *resource.Info, out interface{}) error {
+	u, ok := info.Object.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("invalid object type %T for %s", info.Object, info.Name)
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, out); err != nil {
+		return fmt.Errorf("failed to convert %s to %T: %w", info.Name, out, err)
+	}
+
+	return nil
+}
+
+// infoToPodDisruptionBudgetSelector extracts the Pod label selector from a
+// PodDisruptionBudget, transparently handling both the GA policy/v1 API and
+// the deprecated policy/v1beta1 API (removed in Kubernetes 1.25+). It
+// dispatches on the candidate's GroupVersionKind rather than a type
+// assertion, since resource.Builder's Unstructured() mode always decodes
+// candidates as *unstructured.Unstructured.
+func infoToPodDisruptionBudgetSelector(info *resource.Info) (*metav1.LabelSelector, error) {
+	if info.Object.GetObjectKind().GroupVersionKind().Version == policyv1beta1.SchemeGroupVersion.Version {
+		pdb := &policyv1beta1.PodDisruptionBudget{}
+		if err := infoToTyped(info, pdb); err != nil {
+			return nil, err
+		}
+		return pdb.Spec.Selector, nil
+	}
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	if err := infoToTyped(info, pdb); err != nil {
+		return nil, err
+	}
+	return pdb.Spec.Selector, nil
+}
+
+func (d *determiner) determineUsedPodDisruptionBudget(name string, pdbSelector *metav1.LabelSelector) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(pdbSelector)
 	if err != nil {
-		return false, fmt.Errorf("invalid label selector (%s): %w", pdb.Name, err)
+		return false, fmt.Errorf("invalid label selector (%s): %w", name, err)
 	}
 
 	for _, pod := range d.pods {
@@ -240,3 +591,156 @@ func (d *determiner) determineUsedPodDisruptionBudget(pdb *policyv1beta1.PodDisr
 
 	return false, nil
 }
+
+// checkOwnerRefs decides whether a candidate resource should be pruned given
+// its OwnerReferences: a resource owned by a controller that's still alive
+// is kept, unless --respect-owner-refs was disabled. reason is used as the
+// pruneDecision.Reason when the resource is prunable.
+func (d *determiner) checkOwnerRefs(info *resource.Info, reason string) (pruneDecision, error) {
+	if !d.respectOwnerRefs {
+		return pruneDecision{Prune: true, Reason: reason}, nil
+	}
+
+	accessor, err := meta.Accessor(info.Object)
+	if err != nil {
+		return pruneDecision{}, fmt.Errorf("failed to access object metadata for %s: %w", info.Name, err)
+	}
+
+	hasLiveOwner, err := d.owners.hasLiveOwner(context.Background(), info.Namespace, accessor.GetOwnerReferences())
+	if err != nil {
+		return pruneDecision{}, err
+	}
+
+	if hasLiveOwner {
+		return pruneDecision{}, nil
+	}
+
+	return pruneDecision{Prune: true, Reason: reason}, nil
+}
+
+func detectExistingPersistentVolumeClaims(pvcs []*corev1.PersistentVolumeClaim) map[string]struct{} {
+	existing := make(map[string]struct{}, len(pvcs))
+
+	for _, pvc := range pvcs {
+		existing[pvc.Namespace+"/"+pvc.Name] = struct{}{}
+	}
+
+	return existing
+}
+
+// detectOrphanedPersistentVolume reports whether a PersistentVolume has
+// already been Released by its claim, or whose claimRef points at a
+// PersistentVolumeClaim that no longer exists anywhere in the cluster.
+func detectOrphanedPersistentVolume(pv *corev1.PersistentVolume, existingClaims map[string]struct{}) bool {
+	if pv.Status.Phase == corev1.VolumeReleased {
+		return true
+	}
+
+	claimRef := pv.Spec.ClaimRef
+	if claimRef == nil {
+		return false
+	}
+
+	_, exists := existingClaims[claimRef.Namespace+"/"+claimRef.Name]
+	return !exists
+}
+
+// detectPrunableJob reports whether a Job finished (Complete or Failed) more
+// than jobCompletionTTL ago.
+func detectPrunableJob(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case batchv1.JobComplete, batchv1.JobFailed:
+			return time.Since(cond.LastTransitionTime.Time) > jobCompletionTTL
+		}
+	}
+
+	return false
+}
+
+// detectCurrentReplicaSets returns the names of the ReplicaSets that are the
+// current revision of the Deployment that owns them, per the
+// deploymentRevisionAnnotation. These are kept regardless of replica count,
+// since a Deployment can legitimately scale its current ReplicaSet to zero.
+func detectCurrentReplicaSets(deployments []*appsv1.Deployment, replicaSets []*appsv1.ReplicaSet) map[string]struct{} {
+	revisionByDeploymentUID := make(map[types.UID]string, len(deployments))
+	for _, deployment := range deployments {
+		revisionByDeploymentUID[deployment.UID] = deployment.Annotations[deploymentRevisionAnnotation]
+	}
+
+	current := make(map[string]struct{})
+	for _, rs := range replicaSets {
+		owner := metav1.GetControllerOf(rs)
+		if owner == nil || owner.Kind != "Deployment" {
+			continue
+		}
+
+		revision, ok := revisionByDeploymentUID[owner.UID]
+		if ok && revision != "" && rs.Annotations[deploymentRevisionAnnotation] == revision {
+			current[rs.Name] = struct{}{}
+		}
+	}
+
+	return current
+}
+
+// detectPrunableReplicaSet reports whether a ReplicaSet is scaled to zero
+// replicas and isn't the current revision of the Deployment that owns it.
+func detectPrunableReplicaSet(rs *appsv1.ReplicaSet, currentReplicaSets map[string]struct{}) bool {
+	if rs.Spec.Replicas != nil && *rs.Spec.Replicas > 0 {
+		return false
+	}
+
+	_, isCurrent := currentReplicaSets[rs.Name]
+	return !isCurrent
+}
+
+func infoToPod(info *resource.Info) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	if err := infoToTyped(info, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func infoToPersistentVolume(info *resource.Info) (*corev1.PersistentVolume, error) {
+	pv := &corev1.PersistentVolume{}
+	if err := infoToTyped(info, pv); err != nil {
+		return nil, err
+	}
+	return pv, nil
+}
+
+func infoToJob(info *resource.Info) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	if err := infoToTyped(info, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func infoToReplicaSet(info *resource.Info) (*appsv1.ReplicaSet, error) {
+	rs := &appsv1.ReplicaSet{}
+	if err := infoToTyped(info, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func listAllPersistentVolumeClaims(ctx context.Context, clientset kubernetes.Interface) ([]*corev1.PersistentVolumeClaim, error) {
+	list, err := clientset.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistentvolumeclaims: %w", err)
+	}
+
+	pvcs := make([]*corev1.PersistentVolumeClaim, 0, len(list.Items))
+	for i := range list.Items {
+		pvcs = append(pvcs, &list.Items[i])
+	}
+
+	return pvcs, nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// syntheticPrunePlanCluster builds the usage state and podCount candidate
+// ConfigMaps for a synthetic cluster in which every ConfigMap except each
+// tenth one is referenced (so 90% of candidates are in use) without touching a real or fake API
+// server, so the benchmark below measures determinePrune/newPrunePlanEntry's
+// own cost rather than client-go's.
+func syntheticPrunePlanCluster(podCount int) (*determiner, []*resource.Info) {
+	usedConfigMaps := make(map[string]struct{}, podCount)
+	for i := 0; i < podCount; i++ {
+		if i%10 == 0 {
+			// Every tenth ConfigMap is left unreferenced, so prune plan
+			// generation has a realistic mix of kept and pruned candidates.
+			continue
+		}
+		usedConfigMaps[fmt.Sprintf("default/config-%d", i)] = struct{}{}
+	}
+
+	d := &determiner{usedConfigMaps: usedConfigMaps}
+
+	infos := make([]*resource.Info, 0, podCount)
+	for i := 0; i < podCount; i++ {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("config-%d", i),
+				Namespace: "default",
+				UID:       types.UID(fmt.Sprintf("uid-%d", i)),
+			},
+		}
+		cm.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kindConfigMap))
+		infos = append(infos, &resource.Info{Object: cm, Name: cm.Name, Namespace: cm.Namespace})
+	}
+
+	return d, infos
+}
+
+// BenchmarkPrunePlanGeneration measures determinePrune and newPrunePlanEntry
+// over a synthetic 10k-candidate cluster, standing in for the --output
+// prune plan collectPrunePlan would produce against a cluster of that size.
+func BenchmarkPrunePlanGeneration(b *testing.B) {
+	const candidateCount = 10000
+	d, infos := syntheticPrunePlanCluster(candidateCount)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan := make([]PrunePlanEntry, 0, len(infos))
+		for _, info := range infos {
+			decision, err := d.determinePrune(info)
+			if err != nil {
+				b.Fatalf("determinePrune() error = %v", err)
+			}
+
+			entry, err := newPrunePlanEntry(info, decision)
+			if err != nil {
+				b.Fatalf("newPrunePlanEntry() error = %v", err)
+			}
+			plan = append(plan, entry)
+		}
+
+		if len(plan) != candidateCount {
+			b.Fatalf("expected %d plan entries, got %d", candidateCount, len(plan))
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// unstructuredInfo converts obj to an *unstructured.Unstructured and wraps it
+// in a resource.Info the way resource.Builder's Unstructured() mode actually
+// produces candidates, so tests exercise the real decoding path instead of a
+// hand-built typed struct it would never see in production.
+func unstructuredInfo(t *testing.T, obj interface{}, gvk schema.GroupVersionKind, name, namespace string) *resource.Info {
+	t.Helper()
+
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatalf("ToUnstructured() error = %v", err)
+	}
+
+	u := &unstructured.Unstructured{Object: m}
+	u.SetGroupVersionKind(gvk)
+
+	return &resource.Info{Object: u, Name: name, Namespace: namespace}
+}
+
+// fakeDiscoveryServing reports groupVersion as served, so
+// preferredPodDisruptionBudgetVersion can be exercised against both the GA
+// policy/v1 API and the deprecated policy/v1beta1 API without a real
+// cluster.
+func fakeDiscoveryServing(groupVersion string) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{GroupVersion: groupVersion},
+	}
+	return clientset
+}
+
+func TestPreferredPodDisruptionBudgetVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		serving string
+		want    string
+	}{
+		{name: "modern cluster prefers policy/v1", serving: "policy/v1", want: "policy/v1"},
+		{name: "old cluster falls back to policy/v1beta1", serving: "policy/v1beta1", want: "policy/v1beta1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fakeDiscoveryServing(tt.serving)
+
+			got := preferredPodDisruptionBudgetVersion(clientset)
+			if got != tt.want {
+				t.Errorf("preferredPodDisruptionBudgetVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInfoToPodDisruptionBudgetSelectorBothVersions(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+	t.Run("policy/v1", func(t *testing.T) {
+		pdb := &policyv1.PodDisruptionBudget{Spec: policyv1.PodDisruptionBudgetSpec{Selector: selector}}
+		info := unstructuredInfo(t, pdb, policyv1.SchemeGroupVersion.WithKind(kindPodDisruptionBudget), "pdb", "default")
+
+		got, err := infoToPodDisruptionBudgetSelector(info)
+		if err != nil {
+			t.Fatalf("infoToPodDisruptionBudgetSelector() error = %v", err)
+		}
+		if got.MatchLabels["app"] != "web" {
+			t.Errorf("selector.MatchLabels[app] = %q, want %q", got.MatchLabels["app"], "web")
+		}
+	})
+
+	t.Run("policy/v1beta1", func(t *testing.T) {
+		pdb := &policyv1beta1.PodDisruptionBudget{Spec: policyv1beta1.PodDisruptionBudgetSpec{Selector: selector}}
+		info := unstructuredInfo(t, pdb, policyv1beta1.SchemeGroupVersion.WithKind(kindPodDisruptionBudget), "pdb", "default")
+
+		got, err := infoToPodDisruptionBudgetSelector(info)
+		if err != nil {
+			t.Fatalf("infoToPodDisruptionBudgetSelector() error = %v", err)
+		}
+		if got.MatchLabels["app"] != "web" {
+			t.Errorf("selector.MatchLabels[app] = %q, want %q", got.MatchLabels["app"], "web")
+		}
+	})
+}
+
+// TestDetermineUsedPodDisruptionBudgetBothVersions checks that a selector
+// sourced from either PodDisruptionBudget API version is matched against the
+// determiner's cached Pods the same way.
+func TestDetermineUsedPodDisruptionBudgetBothVersions(t *testing.T) {
+	d := &determiner{
+		pods: []*corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Labels: map[string]string{"app": "web"}}},
+		},
+	}
+
+	matching := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	nonMatching := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "worker"}}
+
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		want     bool
+	}{
+		{name: "matching selector", selector: matching, want: true},
+		{name: "non-matching selector", selector: nonMatching, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.determineUsedPodDisruptionBudget("pdb", tt.selector)
+			if err != nil {
+				t.Fatalf("determineUsedPodDisruptionBudget() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("determineUsedPodDisruptionBudget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestDetectOrphanedPersistentVolume(t *testing.T) {
+	existing := map[string]struct{}{"default/keep-me": {}}
+
+	tests := []struct {
+		name string
+		pv   *corev1.PersistentVolume
+		want bool
+	}{
+		{
+			name: "released volume is orphaned regardless of claimRef",
+			pv: &corev1.PersistentVolume{
+				Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeReleased},
+				Spec:   corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "keep-me"}},
+			},
+			want: true,
+		},
+		{
+			name: "claimRef pointing at a PVC that no longer exists is orphaned",
+			pv: &corev1.PersistentVolume{
+				Spec: corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "gone"}},
+			},
+			want: true,
+		},
+		{
+			name: "claimRef pointing at an existing PVC is not orphaned",
+			pv: &corev1.PersistentVolume{
+				Spec: corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "keep-me"}},
+			},
+			want: false,
+		},
+		{
+			name: "no claimRef is not orphaned",
+			pv:   &corev1.PersistentVolume{},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectOrphanedPersistentVolume(tt.pv, existing); got != tt.want {
+				t.Errorf("detectOrphanedPersistentVolume() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDeterminePrunePersistentVolume exercises determinePrune's
+// infoToPersistentVolume conversion through an Unstructured resource.Info,
+// the way resource.Builder's Unstructured() mode actually constructs
+// candidates, rather than through a hand-built *corev1.PersistentVolume.
+func TestDeterminePrunePersistentVolume(t *testing.T) {
+	d := &determiner{existingPersistentVolumeClaims: map[string]struct{}{"default/keep-me": {}}}
+
+	pv := &corev1.PersistentVolume{
+		Spec: corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "gone"}},
+	}
+	info := unstructuredInfo(t, pv, corev1.SchemeGroupVersion.WithKind(kindPersistentVolume), "orphaned-pv", "")
+
+	decision, err := d.determinePrune(info)
+	if err != nil {
+		t.Fatalf("determinePrune() error = %v", err)
+	}
+	if !decision.Prune {
+		t.Errorf("determinePrune().Prune = false, want true for a PersistentVolume with a claimRef to a missing PVC")
+	}
+}
+
+func TestDetectPrunableJob(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *batchv1.Job
+		want bool
+	}{
+		{
+			name: "completed past the TTL is prunable",
+			job: &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(time.Now().Add(-48 * time.Hour))},
+			}}},
+			want: true,
+		},
+		{
+			name: "failed past the TTL is prunable",
+			job: &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(time.Now().Add(-48 * time.Hour))},
+			}}},
+			want: true,
+		},
+		{
+			name: "completed within the TTL is kept",
+			job: &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour))},
+			}}},
+			want: false,
+		},
+		{
+			name: "still running is kept",
+			job:  &batchv1.Job{},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectPrunableJob(tt.job); got != tt.want {
+				t.Errorf("detectPrunableJob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCurrentReplicaSets(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("deploy-1"), Annotations: map[string]string{deploymentRevisionAnnotation: "3"}},
+	}
+
+	current := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-current",
+			Annotations:     map[string]string{deploymentRevisionAnnotation: "3"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", UID: deployment.UID, Controller: boolPtr(true)}},
+		},
+	}
+	stale := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-stale",
+			Annotations:     map[string]string{deploymentRevisionAnnotation: "2"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", UID: deployment.UID, Controller: boolPtr(true)}},
+		},
+	}
+	unowned := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-unowned"}}
+
+	got := detectCurrentReplicaSets([]*appsv1.Deployment{deployment}, []*appsv1.ReplicaSet{current, stale, unowned})
+
+	if _, ok := got["web-current"]; !ok {
+		t.Errorf("expected web-current to be the current revision")
+	}
+	if _, ok := got["web-stale"]; ok {
+		t.Errorf("expected web-stale not to be the current revision")
+	}
+	if _, ok := got["web-unowned"]; ok {
+		t.Errorf("expected web-unowned not to be the current revision")
+	}
+}
+
+func TestDetectPrunableReplicaSet(t *testing.T) {
+	current := map[string]struct{}{"web-current": {}}
+
+	tests := []struct {
+		name string
+		rs   *appsv1.ReplicaSet
+		want bool
+	}{
+		{
+			name: "scaled to zero and not current is prunable",
+			rs:   &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-old"}, Spec: appsv1.ReplicaSetSpec{Replicas: int32Ptr(0)}},
+			want: true,
+		},
+		{
+			name: "scaled to zero but current revision is kept",
+			rs:   &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-current"}, Spec: appsv1.ReplicaSetSpec{Replicas: int32Ptr(0)}},
+			want: false,
+		},
+		{
+			name: "still scaled up is kept",
+			rs:   &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-old"}, Spec: appsv1.ReplicaSetSpec{Replicas: int32Ptr(2)}},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectPrunableReplicaSet(tt.rs, current); got != tt.want {
+				t.Errorf("detectPrunableReplicaSet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDeterminePrunePodPhase checks that determinePrune distinguishes
+// Succeeded/Failed Pods (prunable, with a phase-specific reason) from a
+// still-Running Pod (kept).
+func TestDeterminePrunePodPhase(t *testing.T) {
+	d := &determiner{}
+
+	tests := []struct {
+		name      string
+		phase     corev1.PodPhase
+		wantPrune bool
+	}{
+		{name: "succeeded", phase: corev1.PodSucceeded, wantPrune: true},
+		{name: "failed", phase: corev1.PodFailed, wantPrune: true},
+		{name: "running", phase: corev1.PodRunning, wantPrune: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Status: corev1.PodStatus{Phase: tt.phase}}
+			info := unstructuredInfo(t, pod, corev1.SchemeGroupVersion.WithKind(kindPod), "some-pod", "default")
+
+			decision, err := d.determinePrune(info)
+			if err != nil {
+				t.Fatalf("determinePrune() error = %v", err)
+			}
+			if decision.Prune != tt.wantPrune {
+				t.Errorf("determinePrune().Prune = %v, want %v", decision.Prune, tt.wantPrune)
+			}
+			if tt.wantPrune && decision.Reason == "" {
+				t.Errorf("expected a non-empty Reason for a prunable Pod")
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool    { return &b }
+func int32Ptr(i int32) *int32 { return &i }
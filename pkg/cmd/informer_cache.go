@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// informerCacheSyncTimeout bounds how long newInformerCache waits for its
+// informers' initial List+Watch handshake before giving up, so a reap run
+// against an unreachable API server fails fast instead of hanging.
+const informerCacheSyncTimeout = 30 * time.Second
+
+// informerCache is a shared, client-go informer-backed cache of the
+// resource kinds newDeterminer cross-references to decide usage. Informers
+// List-and-Watch once up front and then serve every subsequent query from
+// local memory, so a single informerCache backs any number of
+// determinePrune calls - including a --all-namespaces run touching many
+// namespaces - without repeating a List call per kind.
+type informerCache struct {
+	factory informers.SharedInformerFactory
+
+	pods            corelisters.PodLister
+	serviceAccounts corelisters.ServiceAccountLister
+	ingresses       networkinglisters.IngressLister
+	deployments     appslisters.DeploymentLister
+	statefulSets    appslisters.StatefulSetLister
+	daemonSets      appslisters.DaemonSetLister
+	replicaSets     appslisters.ReplicaSetLister
+}
+
+// newInformerCache builds an informerCache scoped to namespace ("" means
+// --all-namespaces) and blocks until the initial cache sync completes, so
+// the listers above are immediately safe to query.
+func newInformerCache(ctx context.Context, clientset kubernetes.Interface, namespace string) (*informerCache, error) {
+	var opts []informers.SharedInformerOption
+	if namespace != "" {
+		opts = append(opts, informers.WithNamespace(namespace))
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, opts...)
+
+	c := &informerCache{
+		factory:         factory,
+		pods:            factory.Core().V1().Pods().Lister(),
+		serviceAccounts: factory.Core().V1().ServiceAccounts().Lister(),
+		ingresses:       factory.Networking().V1().Ingresses().Lister(),
+		deployments:     factory.Apps().V1().Deployments().Lister(),
+		statefulSets:    factory.Apps().V1().StatefulSets().Lister(),
+		daemonSets:      factory.Apps().V1().DaemonSets().Lister(),
+		replicaSets:     factory.Apps().V1().ReplicaSets().Lister(),
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, informerCacheSyncTimeout)
+	defer cancel()
+
+	factory.Start(syncCtx.Done())
+	for informerType, synced := range factory.WaitForCacheSync(syncCtx.Done()) {
+		if !synced {
+			return nil, fmt.Errorf("informer cache for %v failed to sync", informerType)
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewInformerCacheListsSeededObjects(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+	)
+
+	cache, err := newInformerCache(context.Background(), clientset, "default")
+	if err != nil {
+		t.Fatalf("newInformerCache() error = %v", err)
+	}
+
+	pods, err := cache.pods.List(labels.Everything())
+	if err != nil {
+		t.Fatalf("cache.pods.List() error = %v", err)
+	}
+	if len(pods) != 1 || pods[0].Name != "web-1" {
+		t.Errorf("expected one Pod named web-1, got %v", pods)
+	}
+
+	sas, err := cache.serviceAccounts.List(labels.Everything())
+	if err != nil {
+		t.Fatalf("cache.serviceAccounts.List() error = %v", err)
+	}
+	if len(sas) != 1 {
+		t.Errorf("expected one ServiceAccount, got %v", sas)
+	}
+}
+
+func TestNewInformerCacheAllNamespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "other"}},
+	)
+
+	cache, err := newInformerCache(context.Background(), clientset, "")
+	if err != nil {
+		t.Fatalf("newInformerCache() error = %v", err)
+	}
+
+	pods, err := cache.pods.List(labels.Everything())
+	if err != nil {
+		t.Fatalf("cache.pods.List() error = %v", err)
+	}
+	if len(pods) != 2 {
+		t.Errorf("expected pods from both namespaces, got %v", pods)
+	}
+}
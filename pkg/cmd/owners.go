@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ownerResolver determines whether a resource's OwnerReferences point at a
+// controller that is still alive in the cluster, so that kubectl-reap
+// doesn't delete objects a Helm release, an Operator, or a StatefulSet's
+// volumeClaimTemplates would simply recreate.
+type ownerResolver struct {
+	dynamicClient dynamic.Interface
+	mapper        meta.RESTMapper
+
+	// ignoredOwners holds "Kind.group" keys (e.g. "StatefulSet.apps") whose
+	// ownership should be ignored, per --ignore-owners.
+	ignoredOwners map[string]struct{}
+}
+
+func newOwnerResolver(dynamicClient dynamic.Interface, mapper meta.RESTMapper, ignoreOwners []string) *ownerResolver {
+	ignored := make(map[string]struct{}, len(ignoreOwners))
+	for _, kindGroup := range ignoreOwners {
+		if kindGroup == "" {
+			continue
+		}
+		ignored[kindGroup] = struct{}{}
+	}
+
+	return &ownerResolver{
+		dynamicClient: dynamicClient,
+		mapper:        mapper,
+		ignoredOwners: ignored,
+	}
+}
+
+// hasLiveOwner reports whether any controller OwnerReference in refs still
+// points at an object that exists in the cluster. Owners whose "Kind.group"
+// is listed in --ignore-owners are skipped, letting users punch through a
+// stale controller's ownership on purpose.
+func (r *ownerResolver) hasLiveOwner(ctx context.Context, namespace string, refs []metav1.OwnerReference) (bool, error) {
+	for _, ref := range refs {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return false, fmt.Errorf("invalid owner apiVersion (%s) on %s: %w", ref.APIVersion, ref.Name, err)
+		}
+
+		if _, skip := r.ignoredOwners[ref.Kind+"."+gv.Group]; skip {
+			continue
+		}
+
+		alive, err := r.ownerExists(ctx, namespace, gv.WithKind(ref.Kind), ref.Name)
+		if err != nil {
+			return false, err
+		}
+
+		if alive {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *ownerResolver) ownerExists(ctx context.Context, namespace string, gvk schema.GroupVersionKind, name string) (bool, error) {
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, fmt.Errorf("failed to map owner kind %s: %w", gvk, err)
+	}
+
+	resourceClient := r.dynamicClient.Resource(mapping.Resource)
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		_, err = resourceClient.Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	} else {
+		_, err = resourceClient.Get(ctx, name, metav1.GetOptions{})
+	}
+
+	if err == nil {
+		return true, nil
+	}
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("failed to get owner %s/%s: %w", gvk.Kind, name, err)
+}
+
+// parseIgnoreOwners parses a comma-separated --ignore-owners value of the
+// form "Kind.group,Kind.group" (e.g. "StatefulSet.apps,HelmRelease.helm.toolkit.fluxcd.io")
+// into the keys ownerResolver.ignoredOwners expects.
+func parseIgnoreOwners(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func newFakeOwnerResolver(t *testing.T, ignoreOwners []string, objects ...runtime.Object) *ownerResolver {
+	t.Helper()
+
+	dynamicClient := fake.NewSimpleDynamicClient(scheme.Scheme, objects...)
+	mapper := testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)
+
+	return newOwnerResolver(dynamicClient, mapper, ignoreOwners)
+}
+
+func TestHasLiveOwner(t *testing.T) {
+	liveStatefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+	}
+
+	liveRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+		Name:       "web",
+		Controller: boolPtr(true),
+	}
+	deadRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+		Name:       "gone",
+		Controller: boolPtr(true),
+	}
+	nonControllerRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+		Name:       "web",
+		Controller: boolPtr(false),
+	}
+
+	tests := []struct {
+		name         string
+		refs         []metav1.OwnerReference
+		ignoreOwners []string
+		want         bool
+	}{
+		{name: "live controller owner", refs: []metav1.OwnerReference{liveRef}, want: true},
+		{name: "owner no longer exists", refs: []metav1.OwnerReference{deadRef}, want: false},
+		{name: "owner reference isn't a controller", refs: []metav1.OwnerReference{nonControllerRef}, want: false},
+		{name: "no owner references", refs: nil, want: false},
+		{name: "live owner ignored via --ignore-owners", refs: []metav1.OwnerReference{liveRef}, ignoreOwners: []string{"StatefulSet.apps"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newFakeOwnerResolver(t, tt.ignoreOwners, liveStatefulSet)
+
+			got, err := resolver.hasLiveOwner(context.Background(), "default", tt.refs)
+			if err != nil {
+				t.Fatalf("hasLiveOwner() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("hasLiveOwner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIgnoreOwners(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{name: "empty", raw: "", want: nil},
+		{name: "single", raw: "StatefulSet.apps", want: []string{"StatefulSet.apps"}},
+		{name: "multiple", raw: "StatefulSet.apps,HelmRelease.helm.toolkit.fluxcd.io", want: []string{"StatefulSet.apps", "HelmRelease.helm.toolkit.fluxcd.io"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseIgnoreOwners(tt.raw)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseIgnoreOwners() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseIgnoreOwners()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// PrunePlanEntry describes one candidate resource considered for pruning, in
+// a form suitable for --output=json|yaml: a machine-readable prune plan that
+// CI pipelines and GitOps preflight checks can consume without parsing
+// kubectl-reap's human-readable output.
+type PrunePlanEntry struct {
+	Kind      string    `json:"kind" yaml:"kind"`
+	Namespace string    `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name      string    `json:"name" yaml:"name"`
+	UID       types.UID `json:"uid,omitempty" yaml:"uid,omitempty"`
+	Prune     bool      `json:"prune" yaml:"prune"`
+	Reason    string    `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	// Consulted lists the resource kinds determinePrune cross-referenced to
+	// reach Reason, e.g. a ConfigMap decision consults Pods and the
+	// controller kinds whose Pod templates count as usage.
+	Consulted []string `json:"consulted,omitempty" yaml:"consulted,omitempty"`
+}
+
+// newPrunePlanEntry builds the PrunePlanEntry for info given the determiner's
+// decision about it.
+func newPrunePlanEntry(info *resource.Info, decision pruneDecision) (PrunePlanEntry, error) {
+	accessor, err := meta.Accessor(info.Object)
+	if err != nil {
+		return PrunePlanEntry{}, err
+	}
+
+	kind := info.Object.GetObjectKind().GroupVersionKind().Kind
+
+	return PrunePlanEntry{
+		Kind:      kind,
+		Namespace: info.Namespace,
+		Name:      info.Name,
+		UID:       accessor.GetUID(),
+		Prune:     decision.Prune,
+		Reason:    decision.Reason,
+		Consulted: consultedKindsFor(kind),
+	}, nil
+}
+
+// consultedKindsFor lists the resource kinds determinePrune reads from to
+// decide whether a candidate of kind is in use, mirroring the dependencies
+// newDeterminer fetches for that prune kind.
+func consultedKindsFor(kind string) []string {
+	switch kind {
+	case kindConfigMap:
+		return []string{"Pod", "Deployment", "StatefulSet", "DaemonSet", "CronJob", "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration", "APIService"}
+	case kindSecret:
+		return []string{"Pod", "Deployment", "StatefulSet", "DaemonSet", "CronJob", "ServiceAccount", "Ingress", "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration", "APIService", "Certificate"}
+	case kindPersistentVolume:
+		return []string{"PersistentVolumeClaim"}
+	case kindPersistentVolumeClaim:
+		return []string{"Pod"}
+	case kindPodDisruptionBudget:
+		return []string{"Pod"}
+	case kindReplicaSet:
+		return []string{"Deployment", "ReplicaSet"}
+	default:
+		return nil
+	}
+}
+
+// collectPrunePlan walks every candidate resource in r, evaluates it with d,
+// and returns one PrunePlanEntry per candidate (including ones that are kept,
+// so the plan also documents why a resource was spared).
+func collectPrunePlan(d *determiner, r *resource.Result) ([]PrunePlanEntry, error) {
+	var plan []PrunePlanEntry
+
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		decision, err := d.determinePrune(info)
+		if err != nil {
+			return err
+		}
+
+		entry, err := newPrunePlanEntry(info, decision)
+		if err != nil {
+			return err
+		}
+
+		plan = append(plan, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// writePrunePlan renders plan to w in the requested --output format:
+// "json", "yaml", or "table" (the default human-readable form).
+func writePrunePlan(w io.Writer, plan []PrunePlanEntry, format string) error {
+	switch format {
+	case "", "table":
+		return writePrunePlanTable(w, plan)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	case "yaml":
+		out, err := yaml.Marshal(plan)
+		if err != nil {
+			return fmt.Errorf("failed to marshal prune plan as YAML: %w", err)
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return fmt.Errorf("unsupported --output format %q: must be one of json, yaml, table", format)
+	}
+}
+
+func writePrunePlanTable(w io.Writer, plan []PrunePlanEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAMESPACE\tNAME\tPRUNE\tREASON")
+
+	for _, entry := range plan {
+		reason := entry.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\n", entry.Kind, entry.Namespace, entry.Name, entry.Prune, reason)
+	}
+
+	return tw.Flush()
+}
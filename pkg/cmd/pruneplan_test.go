@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func samplePlan() []PrunePlanEntry {
+	return []PrunePlanEntry{
+		{
+			Kind:      kindConfigMap,
+			Namespace: "default",
+			Name:      "unused-config",
+			UID:       types.UID("abc-123"),
+			Prune:     true,
+			Reason:    "ConfigMap not referenced by any Pod, controller, or webhook/APIService caBundle",
+			Consulted: consultedKindsFor(kindConfigMap),
+		},
+	}
+}
+
+func TestWritePrunePlanJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writePrunePlan(&buf, samplePlan(), "json"); err != nil {
+		t.Fatalf("writePrunePlan() error = %v", err)
+	}
+
+	var got []PrunePlanEntry
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output isn't valid JSON: %v\n%s", err, buf.String())
+	}
+
+	if len(got) != 1 || got[0].Name != "unused-config" {
+		t.Errorf("unexpected decoded plan: %+v", got)
+	}
+}
+
+func TestWritePrunePlanYAML(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writePrunePlan(&buf, samplePlan(), "yaml"); err != nil {
+		t.Fatalf("writePrunePlan() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "name: unused-config") {
+		t.Errorf("expected YAML output to contain the entry name, got:\n%s", buf.String())
+	}
+}
+
+func TestWritePrunePlanTable(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writePrunePlan(&buf, samplePlan(), "table"); err != nil {
+		t.Fatalf("writePrunePlan() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "KIND") || !strings.Contains(out, "unused-config") {
+		t.Errorf("expected a table with a header and the entry name, got:\n%s", out)
+	}
+}
+
+func TestWritePrunePlanUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writePrunePlan(&buf, samplePlan(), "xml"); err == nil {
+		t.Errorf("expected an error for an unsupported --output format")
+	}
+}
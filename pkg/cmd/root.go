@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	apiregistrationclientset "k8s.io/kube-aggregator/pkg/client/clientset/versioned"
+)
+
+// ReapOptions holds the flags and clients for the reap command, following
+// the kubectl plugin convention of a single Options struct threaded through
+// Complete/Validate/Run rather than package-level globals.
+type ReapOptions struct {
+	configFlags *genericclioptions.ConfigFlags
+	streams     genericclioptions.IOStreams
+
+	args []string
+
+	respectOwnerRefs bool
+	ignoreOwners     []string
+
+	allNamespaces bool
+	selector      string
+	fieldSelector string
+
+	// maxConcurrency bounds how many of newDeterminer's independent List
+	// calls run at once; zero means defaultMaxConcurrency.
+	maxConcurrency int
+
+	// output is the --output format ("", "table", "json", or "yaml"); any
+	// non-empty value makes Run print the PrunePlanEntry list in that format
+	// instead of deleting anything.
+	output string
+
+	// dryRun is --dry-run's value ("", "client", or "server"); see
+	// deleteCandidate for what each means.
+	dryRun string
+
+	clientset             kubernetes.Interface
+	dynamicClient         dynamic.Interface
+	apiregistrationClient apiregistrationclientset.Interface
+	resources             *resource.Result
+	namespace             string
+}
+
+// NewReapOptions returns a ReapOptions with the same defaults kubectl itself
+// uses for its --respect-owner-refs-equivalent flags: pruning defers to a
+// live controller's ownership unless the user opts out.
+func NewReapOptions(streams genericclioptions.IOStreams) *ReapOptions {
+	return &ReapOptions{
+		configFlags:      genericclioptions.NewConfigFlags(true),
+		streams:          streams,
+		respectOwnerRefs: true,
+	}
+}
+
+// NewCmdReap builds the `kubectl reap` command.
+func NewCmdReap(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewReapOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "reap [(-n|--namespace) namespace] (KIND | TYPE/NAME)",
+		Short: "Delete Kubernetes resources that nothing references anymore",
+		RunE: func(c *cobra.Command, args []string) error {
+			o.args = args
+			if err := o.Complete(c); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.respectOwnerRefs, "respect-owner-refs", o.respectOwnerRefs,
+		"Skip a candidate resource if it's owned by a controller that's still alive, instead of deleting it.")
+	cmd.Flags().StringSliceVar(&o.ignoreOwners, "ignore-owners", o.ignoreOwners,
+		"Comma-separated Kind.group pairs (e.g. StatefulSet.apps) whose ownership --respect-owner-refs should ignore.")
+	cmd.Flags().StringVar(&o.output, "output", o.output,
+		"Output format for a machine-readable prune plan: json, yaml, or table (default: delete and print what was deleted).")
+	cmd.Flags().StringVar(&o.dryRun, "dry-run", o.dryRun,
+		`Must be "client" or "server". If "client", only print what would be deleted without contacting the API server. If "server", submit a server-side dry-run request (DryRun=[All]) so admission webhooks run without anything actually being deleted.`)
+	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", o.allNamespaces,
+		"Consider candidate resources across all namespaces instead of just the current/specified one.")
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", o.selector,
+		"Label selector to filter candidate resources by, as with kubectl get -l.")
+	cmd.Flags().StringVar(&o.fieldSelector, "field-selector", o.fieldSelector,
+		"Field selector to filter candidate resources by, as with kubectl get --field-selector.")
+	cmd.Flags().IntVar(&o.maxConcurrency, "max-concurrency", defaultMaxConcurrency,
+		"Maximum number of newDeterminer's independent List calls to run at once.")
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Complete fills in the clients and resource.Result that Run needs, based on
+// the flags and positional arguments the user supplied.
+func (o *ReapOptions) Complete(cmd *cobra.Command) error {
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to build REST config: %w", err)
+	}
+
+	o.clientset, err = kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build clientset: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build dynamic client: %w", err)
+	}
+
+	o.apiregistrationClient, err = apiregistrationclientset.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build apiregistration client: %w", err)
+	}
+
+	o.namespace, _, err = o.configFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("failed to determine namespace: %w", err)
+	}
+
+	o.resources = resource.NewBuilder(o.configFlags).
+		Unstructured().
+		NamespaceParam(o.namespace).DefaultNamespace().AllNamespaces(o.allNamespaces).
+		LabelSelectorParam(o.selector).
+		FieldSelectorParam(o.fieldSelector).
+		ResourceTypeOrNameArgs(true, o.args...).
+		Flatten().
+		Do()
+
+	return nil
+}
+
+// Validate checks the options Complete produced before Run acts on them.
+func (o *ReapOptions) Validate() error {
+	if len(o.args) == 0 {
+		return fmt.Errorf("must specify at least one resource kind to reap")
+	}
+
+	switch o.output {
+	case "", "table", "json", "yaml":
+	default:
+		return fmt.Errorf("unsupported --output format %q: must be one of json, yaml, table", o.output)
+	}
+
+	switch o.dryRun {
+	case "", dryRunClient, dryRunServer:
+	default:
+		return fmt.Errorf("unsupported --dry-run value %q: must be %q or %q", o.dryRun, dryRunClient, dryRunServer)
+	}
+
+	return nil
+}
+
+// Run evaluates every candidate resource.Builder produced and deletes the
+// ones determinePrune judges unused.
+func (o *ReapOptions) Run() error {
+	mapper, err := o.configFlags.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("failed to build REST mapper: %w", err)
+	}
+
+	d, err := newDeterminer(determinerConfig{
+		clientset:             o.clientset,
+		dynamicClient:         o.dynamicClient,
+		apiregistrationClient: o.apiregistrationClient,
+		mapper:                mapper,
+		resources:             o.resources,
+		namespace:             o.namespace,
+		allNamespaces:         o.allNamespaces,
+		respectOwnerRefs:      o.respectOwnerRefs,
+		ignoreOwners:          o.ignoreOwners,
+		maxConcurrency:        o.maxConcurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	// --output requests a machine-readable prune plan instead of the normal
+	// delete-and-report behavior, so CI pipelines and GitOps preflight checks
+	// can consume kubectl-reap's decisions without actually deleting anything.
+	if o.output != "" {
+		plan, err := collectPrunePlan(d, o.resources)
+		if err != nil {
+			return err
+		}
+		return writePrunePlan(o.streams.Out, plan, o.output)
+	}
+
+	ctx := context.Background()
+
+	return o.resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		decision, err := d.determinePrune(info)
+		if err != nil {
+			return err
+		}
+		if !decision.Prune {
+			return nil
+		}
+
+		if err := deleteCandidate(ctx, o.dynamicClient, mapper, info, o.dryRun); err != nil {
+			return err
+		}
+
+		suffix := ""
+		if o.dryRun != "" {
+			suffix = fmt.Sprintf(" (dry run: %s)", o.dryRun)
+		}
+		fmt.Fprintf(o.streams.Out, "%s/%s: %s%s\n", info.Object.GetObjectKind().GroupVersionKind().Kind, info.Name, decision.Reason, suffix)
+		return nil
+	})
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	apiregistrationclientset "k8s.io/kube-aggregator/pkg/client/clientset/versioned"
+)
+
+// caBundleSecretAnnotation is set by cert-manager's CA injector
+// (https://cert-manager.io/docs/concepts/ca-injector/) on webhook
+// configurations and APIServices to source their caBundle from a Secret,
+// as "namespace/name".
+const caBundleSecretAnnotation = "cert-manager.io/inject-ca-from-secret"
+
+// caBundleConfigMapAnnotation is the CA injector's ConfigMap-sourced
+// equivalent of caBundleSecretAnnotation, used when a caBundle is kept in a
+// ConfigMap (e.g. a cluster's trust bundle) rather than a Secret.
+const caBundleConfigMapAnnotation = "cert-manager.io/inject-ca-from-configmap"
+
+// certificateGVR is the cert-manager Certificate CRD kubectl-reap reads via
+// the dynamic client, since it doesn't otherwise depend on cert-manager's
+// typed clientset.
+var certificateGVR = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "certificates",
+}
+
+// detectIngressSecrets returns the "namespace/name" Secrets referenced by
+// Ingress TLS blocks.
+func detectIngressSecrets(ingresses []*networkingv1.Ingress) map[string]struct{} {
+	usedSecrets := make(map[string]struct{})
+
+	for _, ingress := range ingresses {
+		for _, tls := range ingress.Spec.TLS {
+			if tls.SecretName != "" {
+				usedSecrets[ingress.Namespace+"/"+tls.SecretName] = struct{}{}
+			}
+		}
+	}
+
+	return usedSecrets
+}
+
+// detectCABundleSecrets returns the "namespace/name" Secrets referenced by
+// the cert-manager CA-injector annotation on the given annotation sets.
+func detectCABundleSecrets(annotationSets []map[string]string) map[string]struct{} {
+	return detectCABundleRefs(caBundleSecretAnnotation, annotationSets)
+}
+
+// detectCABundleConfigMaps returns the "namespace/name" ConfigMaps
+// referenced by the cert-manager CA-injector's ConfigMap-sourced annotation
+// on the given annotation sets, so a ConfigMap backing a webhook or
+// APIService caBundle isn't wrongly reaped.
+func detectCABundleConfigMaps(annotationSets []map[string]string) map[string]struct{} {
+	return detectCABundleRefs(caBundleConfigMapAnnotation, annotationSets)
+}
+
+// detectCABundleRefs extracts the "namespace/name" value of annotationKey
+// from each annotation set. The annotation value is already in the same
+// "namespace/name" form usedSecrets/usedConfigMaps key on, so it's kept
+// verbatim rather than filtered down to a single namespace.
+func detectCABundleRefs(annotationKey string, annotationSets []map[string]string) map[string]struct{} {
+	used := make(map[string]struct{})
+
+	for _, annotations := range annotationSets {
+		if ref, ok := annotations[annotationKey]; ok {
+			used[ref] = struct{}{}
+		}
+	}
+
+	return used
+}
+
+// detectCertManagerSecrets returns the "namespace/name" Secrets referenced
+// by cert-manager Certificate CRs' spec.secretName.
+func detectCertManagerSecrets(certificates []*unstructured.Unstructured) map[string]struct{} {
+	usedSecrets := make(map[string]struct{})
+
+	for _, cert := range certificates {
+		secretName, found, err := unstructured.NestedString(cert.Object, "spec", "secretName")
+		if err != nil || !found {
+			continue
+		}
+		usedSecrets[cert.GetNamespace()+"/"+secretName] = struct{}{}
+	}
+
+	return usedSecrets
+}
+
+// listWebhookAndAPIServiceAnnotations returns the annotations of every
+// MutatingWebhookConfiguration, ValidatingWebhookConfiguration, and
+// APIService in the cluster, so detectCABundleSecrets can look for
+// cert-manager's caBundleSecretAnnotation without depending on each type
+// individually.
+func listWebhookAndAPIServiceAnnotations(ctx context.Context, clientset kubernetes.Interface, apiregistrationClient apiregistrationclientset.Interface) ([]map[string]string, error) {
+	var annotationSets []map[string]string
+
+	mutating, err := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutatingwebhookconfigurations: %w", err)
+	}
+	for i := range mutating.Items {
+		annotationSets = append(annotationSets, mutating.Items[i].Annotations)
+	}
+
+	validating, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validatingwebhookconfigurations: %w", err)
+	}
+	for i := range validating.Items {
+		annotationSets = append(annotationSets, validating.Items[i].Annotations)
+	}
+
+	apiServices, err := apiregistrationClient.ApiregistrationV1().APIServices().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list apiservices: %w", err)
+	}
+	for i := range apiServices.Items {
+		annotationSets = append(annotationSets, apiServices.Items[i].Annotations)
+	}
+
+	return annotationSets, nil
+}
+
+// listControllerPodSpecs returns the Pod template spec of every Deployment,
+// StatefulSet, DaemonSet, and CronJob in namespace ("" meaning every
+// namespace, as under --all-namespaces), paired with the namespace each was
+// read from, so a ConfigMap/Secret referenced only by a scaled-to-zero
+// controller isn't wrongly reaped. Deployments/StatefulSets/DaemonSets are
+// served from cache; CronJobs have no informer above and are still fetched
+// with a one-shot List call.
+func listControllerPodSpecs(ctx context.Context, clientset kubernetes.Interface, cache *informerCache, namespace string) ([]namespacedPodSpec, error) {
+	var specs []namespacedPodSpec
+
+	deployments, err := cache.deployments.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments from informer cache: %w", err)
+	}
+	for _, deployment := range deployments {
+		specs = append(specs, namespacedPodSpec{namespace: deployment.Namespace, spec: deployment.Spec.Template.Spec})
+	}
+
+	statefulSets, err := cache.statefulSets.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets from informer cache: %w", err)
+	}
+	for _, statefulSet := range statefulSets {
+		specs = append(specs, namespacedPodSpec{namespace: statefulSet.Namespace, spec: statefulSet.Spec.Template.Spec})
+	}
+
+	daemonSets, err := cache.daemonSets.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets from informer cache: %w", err)
+	}
+	for _, daemonSet := range daemonSets {
+		specs = append(specs, namespacedPodSpec{namespace: daemonSet.Namespace, spec: daemonSet.Spec.Template.Spec})
+	}
+
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronJobs.Items {
+		specs = append(specs, namespacedPodSpec{namespace: cronJobs.Items[i].Namespace, spec: cronJobs.Items[i].Spec.JobTemplate.Spec.Template.Spec})
+	}
+
+	return specs, nil
+}
+
+// listCertManagerCertificates lists cert-manager Certificates in namespace
+// ("" meaning every namespace, as under --all-namespaces) via the dynamic
+// client. It returns an empty result rather than an error when the CRD
+// isn't installed, since cert-manager integration is optional.
+func listCertManagerCertificates(ctx context.Context, dynamicClient dynamic.Interface, namespace string) ([]*unstructured.Unstructured, error) {
+	list, err := dynamicClient.Resource(certificateGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list cert-manager certificates: %w", err)
+	}
+
+	certificates := make([]*unstructured.Unstructured, 0, len(list.Items))
+	for i := range list.Items {
+		certificates = append(certificates, &list.Items[i])
+	}
+
+	return certificates, nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDetectIngressSecrets(t *testing.T) {
+	ingresses := []*networkingv1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+			Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{
+				{SecretName: "web-tls"},
+				{SecretName: ""},
+			}},
+		},
+	}
+
+	got := detectIngressSecrets(ingresses)
+
+	if _, ok := got["default/web-tls"]; !ok {
+		t.Errorf("expected default/web-tls to be detected as used")
+	}
+	if len(got) != 1 {
+		t.Errorf("expected exactly one used Secret, got %v", got)
+	}
+}
+
+func TestDetectCABundleSecrets(t *testing.T) {
+	annotationSets := []map[string]string{
+		{caBundleSecretAnnotation: "default/ca-secret"},
+		{caBundleSecretAnnotation: "other-ns/other-secret"},
+		{"unrelated": "annotation"},
+	}
+
+	got := detectCABundleSecrets(annotationSets)
+
+	if _, ok := got["default/ca-secret"]; !ok {
+		t.Errorf("expected default/ca-secret to be detected as used")
+	}
+	if _, ok := got["other-ns/other-secret"]; !ok {
+		t.Errorf("expected other-ns/other-secret (different namespace) to also be detected as used")
+	}
+}
+
+func TestDetectCABundleConfigMaps(t *testing.T) {
+	annotationSets := []map[string]string{
+		{caBundleConfigMapAnnotation: "default/trust-bundle"},
+		{caBundleConfigMapAnnotation: "other-ns/other-bundle"},
+	}
+
+	got := detectCABundleConfigMaps(annotationSets)
+
+	if _, ok := got["default/trust-bundle"]; !ok {
+		t.Errorf("expected default/trust-bundle to be detected as used")
+	}
+	if _, ok := got["other-ns/other-bundle"]; !ok {
+		t.Errorf("expected other-ns/other-bundle (different namespace) to also be detected as used")
+	}
+}
+
+func TestDetectCertManagerSecrets(t *testing.T) {
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"secretName": "web-cert"},
+	}}
+	cert.SetNamespace("default")
+	noSecretName := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{},
+	}}
+
+	got := detectCertManagerSecrets([]*unstructured.Unstructured{cert, noSecretName})
+
+	if _, ok := got["default/web-cert"]; !ok {
+		t.Errorf("expected default/web-cert to be detected as used")
+	}
+	if len(got) != 1 {
+		t.Errorf("expected exactly one used Secret, got %v", got)
+	}
+}